@@ -0,0 +1,130 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BufferOptions configures how an output buffers records before flushing.
+// StorageType uses Fluent Bit's vocabulary ("filesystem" or "memory") and
+// is translated to Fluentd's buffer @type ("file" or "memory") on render,
+// so callers declare buffering once for both backends.
+type BufferOptions struct {
+	StorageType      string // "filesystem" or "memory"
+	Path             string // Fluentd buffer path
+	ChunkLimitSize   string
+	FlushInterval    string
+	RetryLimit       string // Fluent Bit Retry_Limit
+	RetryType        string // Fluentd retry_type, e.g. "exponential_backoff"
+	RetryMaxInterval string
+	OverflowAction   string
+}
+
+// ServiceStorage configures the Fluent Bit [SERVICE] storage.* keys that
+// back filesystem buffering. It has no Fluentd equivalent.
+type ServiceStorage struct {
+	Path            string
+	Sync            string
+	Checksum        string
+	BacklogMemLimit string
+}
+
+// AddBuffer attaches buffering configuration to the most recently added
+// output whose match pattern is match.
+func (c *Config) AddBuffer(match string, opts BufferOptions) *Config {
+	for i := len(c.outputs) - 1; i >= 0; i-- {
+		if c.outputs[i].tag == match {
+			c.outputs[i].buffer = &opts
+			break
+		}
+	}
+	return c
+}
+
+// WithStorage configures the Fluent Bit [SERVICE] storage.* stanza used by
+// filesystem-backed buffers.
+func (c *Config) WithStorage(s ServiceStorage) *Config {
+	c.serviceStorage = &s
+	return c
+}
+
+func writeServiceStorage(sb *strings.Builder, s *ServiceStorage) {
+	if s == nil {
+		return
+	}
+	sb.WriteString("[SERVICE]\n")
+	if s.Path != "" {
+		fmt.Fprintf(sb, "    storage.path %s\n", s.Path)
+	}
+	if s.Sync != "" {
+		fmt.Fprintf(sb, "    storage.sync %s\n", s.Sync)
+	}
+	if s.Checksum != "" {
+		fmt.Fprintf(sb, "    storage.checksum %s\n", s.Checksum)
+	}
+	if s.BacklogMemLimit != "" {
+		fmt.Fprintf(sb, "    storage.backlog.mem_limit %s\n", s.BacklogMemLimit)
+	}
+	sb.WriteString("\n")
+}
+
+func writeFluentBitBuffer(sb *strings.Builder, b *BufferOptions) {
+	if b == nil {
+		return
+	}
+	if b.StorageType != "" {
+		fmt.Fprintf(sb, "    storage.type %s\n", b.StorageType)
+	}
+	if b.RetryLimit != "" {
+		fmt.Fprintf(sb, "    Retry_Limit %s\n", b.RetryLimit)
+	}
+}
+
+func fluentdBufferType(storageType string) string {
+	if storageType == "filesystem" {
+		return "file"
+	}
+	return storageType
+}
+
+func writeFluentdBuffer(sb *strings.Builder, b *BufferOptions) {
+	if b == nil {
+		return
+	}
+	sb.WriteString("    <buffer tag>\n")
+	if t := fluentdBufferType(b.StorageType); t != "" {
+		fmt.Fprintf(sb, "        @type %s\n", t)
+	}
+	if b.Path != "" {
+		fmt.Fprintf(sb, "        path %s\n", b.Path)
+	}
+	if b.ChunkLimitSize != "" {
+		fmt.Fprintf(sb, "        chunk_limit_size %s\n", b.ChunkLimitSize)
+	}
+	if b.FlushInterval != "" {
+		fmt.Fprintf(sb, "        flush_interval %s\n", b.FlushInterval)
+	}
+	if b.RetryType != "" {
+		fmt.Fprintf(sb, "        retry_type %s\n", b.RetryType)
+	}
+	if b.RetryMaxInterval != "" {
+		fmt.Fprintf(sb, "        retry_max_interval %s\n", b.RetryMaxInterval)
+	}
+	if b.OverflowAction != "" {
+		fmt.Fprintf(sb, "        overflow_action %s\n", b.OverflowAction)
+	}
+	sb.WriteString("    </buffer>\n")
+}
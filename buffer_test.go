@@ -0,0 +1,80 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddBuffer(t *testing.T) {
+	config := New()
+	config.WithStorage(ServiceStorage{
+		Path: "/var/log/flb-storage/",
+		Sync: "normal",
+	})
+	config.AddOutput("cloudwath", "*", map[string]string{
+		"log_group_name": "my-group",
+		"region":         "us-west-2",
+	})
+	config.AddBuffer("*", BufferOptions{
+		StorageType:      "filesystem",
+		Path:             "/var/log/fluent/buffer",
+		ChunkLimitSize:   "8MB",
+		FlushInterval:    "5s",
+		RetryLimit:       "5",
+		RetryType:        "exponential_backoff",
+		RetryMaxInterval: "30s",
+		OverflowAction:   "block",
+	})
+
+	var expectedFluentBitConfig = `[SERVICE]
+    storage.path /var/log/flb-storage/
+    storage.sync normal
+
+[OUTPUT]
+    Name cloudwath
+    Match *
+    log_group_name my-group
+    region us-west-2
+    storage.type filesystem
+    Retry_Limit 5
+`
+
+	var expectedFluentdConfig = `<match *>
+    @type cloudwath
+    log_group_name my-group
+    region us-west-2
+    <buffer tag>
+        @type file
+        path /var/log/fluent/buffer
+        chunk_limit_size 8MB
+        flush_interval 5s
+        retry_type exponential_backoff
+        retry_max_interval 30s
+        overflow_action block
+    </buffer>
+</match>
+`
+
+	fluentbitConfig := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentBitConfig(fluentbitConfig))
+	assert.Equal(t, expectedFluentBitConfig, fluentbitConfig.String(), "Expected Fluent Bit Config to match")
+
+	fluentDConfig := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentdConfig(fluentDConfig))
+	assert.Equal(t, expectedFluentdConfig, fluentDConfig.String(), "Expected FluentD Config to match")
+}
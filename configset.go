@@ -0,0 +1,109 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generator
+
+import "fmt"
+
+// Selector is a set of label key/value pairs. It is used both to label a
+// ConfigSet fragment and, symmetrically, to select which labeled fragments
+// should be included when a Config is built. A selector matches a
+// fragment's labels when every key/value pair in the selector is present
+// in the labels (extra labels on the fragment are ignored).
+type Selector map[string]string
+
+func (sel Selector) matches(labels Selector) bool {
+	for k, v := range sel {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+type csFragment struct {
+	labels Selector
+	add    func(*Config)
+}
+
+// ConfigSet stores inputs, filters and outputs as independently-labeled
+// fragments, and assembles a *Config from only the fragments matching a
+// given Selector. This lets library consumers such as operators and
+// controllers compose a Fluentd/Fluent Bit config by selection, the way
+// fluent-operator builds a ClusterFluentdConfig out of many CRs, rather
+// than by imperative chaining against a single Config.
+type ConfigSet struct {
+	fragments []csFragment
+}
+
+// NewConfigSet returns an empty ConfigSet.
+func NewConfigSet() *ConfigSet {
+	return &ConfigSet{}
+}
+
+// AddInput registers an input fragment under labels.
+func (cs *ConfigSet) AddInput(labels Selector, name, tag string, params map[string]string) *ConfigSet {
+	return cs.AddFragment(labels, func(c *Config) {
+		c.AddInput(name, tag, params)
+	})
+}
+
+// AddOutput registers an output fragment under labels.
+func (cs *ConfigSet) AddOutput(labels Selector, name, match string, params map[string]string) *ConfigSet {
+	return cs.AddFragment(labels, func(c *Config) {
+		c.AddOutput(name, match, params)
+	})
+}
+
+// AddFragment registers an arbitrary piece of Config construction under
+// labels. Use this for fragments AddInput/AddOutput don't cover, such as
+// filters, buffers or typed outputs, e.g.:
+//
+//	cs.AddFragment(labels, func(c *generator.Config) {
+//	    c.AddIncludeFilter(pattern, key, match)
+//	})
+func (cs *ConfigSet) AddFragment(labels Selector, add func(*Config)) *ConfigSet {
+	cs.fragments = append(cs.fragments, csFragment{labels: labels, add: add})
+	return cs
+}
+
+// Build assembles a *Config out of every fragment whose labels match
+// selector.
+func (cs *ConfigSet) Build(selector Selector) *Config {
+	c := New()
+	for _, f := range cs.fragments {
+		if selector.matches(f.labels) {
+			f.add(c)
+		}
+	}
+	return c
+}
+
+// NamespaceSelector returns a Fluent tag/match pattern selecting every
+// container log tag in the given Kubernetes namespace.
+func NamespaceSelector(namespace string) string {
+	return fmt.Sprintf("kube.var.log.containers.*_%s_*", namespace)
+}
+
+// ContainerSelector returns a Fluent tag/match pattern selecting every
+// container log tag for the given container name, across all pods and
+// namespaces.
+func ContainerSelector(container string) string {
+	return fmt.Sprintf("kube.var.log.containers.*_*_%s*", container)
+}
+
+// PodSelector returns a Fluent tag/match pattern selecting every container
+// log tag for the pod named pod, across all namespaces and containers.
+func PodSelector(pod string) string {
+	return fmt.Sprintf("kube.var.log.containers.%s_*_*", pod)
+}
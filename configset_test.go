@@ -0,0 +1,63 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSetBuildBySelector(t *testing.T) {
+	cs := NewConfigSet()
+	cs.AddOutput(Selector{"tier": "prod"}, "cloudwatch", "*", map[string]string{
+		"region":         "us-west-2",
+		"log_group_name": "prod-group",
+	})
+	cs.AddOutput(Selector{"tier": "dev"}, "cloudwatch", "*", map[string]string{
+		"region":         "us-west-2",
+		"log_group_name": "dev-group",
+	})
+
+	prod := cs.Build(Selector{"tier": "prod"})
+
+	var expected = `[OUTPUT]
+    Name cloudwatch
+    Match *
+    log_group_name prod-group
+    region us-west-2
+`
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, prod.WriteFluentBitConfig(buf))
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestConfigSetSelectorRequiresAllLabels(t *testing.T) {
+	cs := NewConfigSet()
+	cs.AddInput(Selector{"tier": "prod", "region": "us-west-2"}, "forward", "tag", nil)
+
+	empty := cs.Build(Selector{"tier": "prod", "region": "us-east-1"})
+	assert.Len(t, empty.inputs, 0)
+
+	matched := cs.Build(Selector{"tier": "prod"})
+	assert.Len(t, matched.inputs, 1)
+}
+
+func TestNamespaceAndContainerSelector(t *testing.T) {
+	assert.Equal(t, "kube.var.log.containers.*_kube-system_*", NamespaceSelector("kube-system"))
+	assert.Equal(t, "kube.var.log.containers.*_*_app*", ContainerSelector("app"))
+	assert.Equal(t, "kube.var.log.containers.web-1_*_*", PodSelector("web-1"))
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddLuaFilter(t *testing.T) {
+	config := New()
+	config.AddLuaFilter("redact", "/fluent-bit/scripts/redact.lua", "redact_fields", "*", map[string]string{
+		"type_int_key": "log",
+	})
+
+	var expectedFluentBitConfig = `[FILTER]
+    Name lua
+    Match *
+    Alias redact
+    script /fluent-bit/scripts/redact.lua
+    call redact_fields
+    type_int_key log
+`
+
+	var expectedFluentdConfig = `<filter *>
+    @type script
+    @id redact
+    path /fluent-bit/scripts/redact.lua
+    call redact_fields
+    type_int_key log
+</filter>
+`
+
+	fluentbitConfig := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentBitConfig(fluentbitConfig))
+	assert.Equal(t, expectedFluentBitConfig, fluentbitConfig.String())
+
+	fluentDConfig := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentdConfig(fluentDConfig))
+	assert.Equal(t, expectedFluentdConfig, fluentDConfig.String())
+}
+
+func TestAddGoFilterHasNoFluentdEquivalent(t *testing.T) {
+	config := New()
+	config.AddGoFilter("geoip", "/fluent-bit/plugins/geoip.so", "*", map[string]string{
+		"db_path": "/usr/share/GeoIP/GeoLite2-City.mmdb",
+	})
+
+	var expectedFluentBitConfig = `[FILTER]
+    Name geoip
+    Match *
+    Path /fluent-bit/plugins/geoip.so
+    db_path /usr/share/GeoIP/GeoLite2-City.mmdb
+`
+
+	fluentbitConfig := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentBitConfig(fluentbitConfig))
+	assert.Equal(t, expectedFluentBitConfig, fluentbitConfig.String())
+
+	fluentDConfig := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentdConfig(fluentDConfig))
+	assert.Equal(t, "# no Fluentd equivalent for Go filter \"geoip\", skipped\n", fluentDConfig.String())
+
+	assert.Len(t, config.Warnings(), 1)
+	assert.Contains(t, config.Warnings()[0], "geoip")
+}
+
+func TestEmitPluginScaffold(t *testing.T) {
+	config := New()
+	config.AddGoFilter("geoip", "/fluent-bit/plugins/geoip.so", "*", nil)
+
+	dir := t.TempDir()
+	assert.NoError(t, config.EmitPluginScaffold(dir))
+
+	contents, err := os.ReadFile(filepath.Join(dir, "geoip.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(contents), "func FLBPluginRegister(ctx unsafe.Pointer) int {")
+	assert.Contains(t, string(contents), "func FLBPluginFilter(data unsafe.Pointer, length C.int, tag *C.char) int {")
+	assert.Contains(t, string(contents), `"geoip"`)
+}
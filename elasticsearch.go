@@ -0,0 +1,133 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generator
+
+// ElasticsearchOutputOptions configures an Elasticsearch output, including
+// the data-stream fields Fluent Bit's es plugin and Fluentd's
+// elasticsearch/elasticsearch_data_stream plugins both understand. Fields
+// left at their zero value are omitted from the rendered config.
+type ElasticsearchOutputOptions struct {
+	Host     string
+	Port     string
+	Scheme   string
+	User     string
+	Password string
+
+	CloudID   string
+	CloudAuth string
+
+	DataStreamName         string
+	DataStreamTemplateName string
+
+	// Type is the ES6-style document type. Fluent Bit's es plugin
+	// requires one even when the cluster ignores it; Fluentd's
+	// elasticsearch plugin calls the equivalent key type_name.
+	Type string
+
+	IndexName      string
+	LogstashFormat bool
+	LogstashPrefix string
+	TargetIndexKey string
+
+	TLS       bool
+	TLSVerify bool
+
+	Buffer *BufferOptions
+}
+
+// AddElasticsearchOutput registers an Elasticsearch output for match,
+// choosing the data-stream plugin over the classic one for Fluentd when
+// DataStreamName is set, and rendering the Fluent Bit and Fluentd specific
+// key names for each field.
+func (c *Config) AddElasticsearchOutput(match string, opts ElasticsearchOutputOptions) *Config {
+	bitParams := map[string]string{}
+	fdParams := map[string]string{}
+
+	if opts.Host != "" {
+		bitParams["Host"] = opts.Host
+		fdParams["host"] = opts.Host
+	}
+	if opts.Port != "" {
+		bitParams["Port"] = opts.Port
+		fdParams["port"] = opts.Port
+	}
+	if opts.Scheme != "" {
+		fdParams["scheme"] = opts.Scheme
+	}
+	if opts.User != "" {
+		bitParams["HTTP_User"] = opts.User
+		fdParams["user"] = opts.User
+	}
+	if opts.Password != "" {
+		bitParams["HTTP_Passwd"] = opts.Password
+		fdParams["password"] = opts.Password
+	}
+	if opts.CloudID != "" {
+		bitParams["Cloud_ID"] = opts.CloudID
+		fdParams["cloud_id"] = opts.CloudID
+	}
+	if opts.CloudAuth != "" {
+		bitParams["Cloud_Auth"] = opts.CloudAuth
+		fdParams["cloud_auth"] = opts.CloudAuth
+	}
+	if opts.DataStreamName != "" {
+		bitParams["Index"] = opts.DataStreamName
+		bitParams["Suppress_Type_Name"] = "On"
+		fdParams["data_stream_name"] = opts.DataStreamName
+	} else if opts.IndexName != "" {
+		bitParams["Index"] = opts.IndexName
+		fdParams["index_name"] = opts.IndexName
+	}
+	if opts.DataStreamTemplateName != "" {
+		fdParams["data_stream_template_name"] = opts.DataStreamTemplateName
+	}
+	if opts.Type != "" {
+		bitParams["Type"] = opts.Type
+		fdParams["type_name"] = opts.Type
+	}
+	if opts.LogstashFormat {
+		bitParams["Logstash_Format"] = "On"
+		fdParams["logstash_format"] = "true"
+	}
+	if opts.LogstashPrefix != "" {
+		bitParams["Logstash_Prefix"] = opts.LogstashPrefix
+		fdParams["logstash_prefix"] = opts.LogstashPrefix
+	}
+	if opts.TargetIndexKey != "" {
+		fdParams["target_index_key"] = opts.TargetIndexKey
+	}
+	if opts.TLS {
+		bitParams["tls"] = "On"
+	}
+	if opts.TLSVerify {
+		bitParams["tls.verify"] = "On"
+		fdParams["ssl_verify"] = "true"
+	}
+
+	fdName := "elasticsearch"
+	if opts.DataStreamName != "" {
+		fdName = "elasticsearch_data_stream"
+	}
+
+	block := ioBlock{
+		name:          "es",
+		tag:           match,
+		params:        bitParams,
+		buffer:        opts.Buffer,
+		fluentdName:   fdName,
+		fluentdParams: fdParams,
+	}
+	c.outputs = append(c.outputs, block)
+	return c
+}
@@ -0,0 +1,104 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddElasticsearchOutputDataStream(t *testing.T) {
+	config := New()
+	config.AddElasticsearchOutput("logs.**", ElasticsearchOutputOptions{
+		Host:                   "es.example.com",
+		Port:                   "9200",
+		DataStreamName:         "logs-myapp-default",
+		DataStreamTemplateName: "logs-myapp",
+		TLS:                    true,
+		TLSVerify:              true,
+	})
+
+	var expectedFluentBitConfig = `[OUTPUT]
+    Name es
+    Match logs.**
+    Host es.example.com
+    Index logs-myapp-default
+    Port 9200
+    Suppress_Type_Name On
+    tls On
+    tls.verify On
+`
+
+	var expectedFluentdConfig = `<match logs.**>
+    @type elasticsearch_data_stream
+    data_stream_name logs-myapp-default
+    data_stream_template_name logs-myapp
+    host es.example.com
+    port 9200
+    ssl_verify true
+</match>
+`
+
+	fluentbitConfig := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentBitConfig(fluentbitConfig))
+	assert.Equal(t, expectedFluentBitConfig, fluentbitConfig.String(), "Expected Fluent Bit Config to match")
+
+	fluentDConfig := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentdConfig(fluentDConfig))
+	assert.Equal(t, expectedFluentdConfig, fluentDConfig.String(), "Expected FluentD Config to match")
+}
+
+func TestAddElasticsearchOutputClassic(t *testing.T) {
+	config := New()
+	config.AddElasticsearchOutput("*", ElasticsearchOutputOptions{
+		Host:           "es.example.com",
+		Port:           "9200",
+		IndexName:      "myapp",
+		Type:           "_doc",
+		LogstashFormat: true,
+		LogstashPrefix: "myapp",
+	})
+
+	var expectedFluentBitConfig = `[OUTPUT]
+    Name es
+    Match *
+    Host es.example.com
+    Index myapp
+    Logstash_Format On
+    Logstash_Prefix myapp
+    Port 9200
+    Type _doc
+`
+
+	var expectedFluentdConfig = `<match *>
+    @type elasticsearch
+    host es.example.com
+    index_name myapp
+    logstash_format true
+    logstash_prefix myapp
+    port 9200
+    type_name _doc
+</match>
+`
+
+	fluentbitConfig := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentBitConfig(fluentbitConfig))
+	assert.Equal(t, expectedFluentBitConfig, fluentbitConfig.String(), "Expected Fluent Bit Config to match")
+
+	fluentDConfig := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentdConfig(fluentDConfig))
+	assert.Equal(t, expectedFluentdConfig, fluentDConfig.String(), "Expected FluentD Config to match")
+}
@@ -0,0 +1,394 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package generator builds Fluentd and Fluent Bit configuration files
+// programmatically, so that callers do not need to hand-write the two
+// config languages (or keep them in sync with each other).
+package generator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// InsertionPoint identifies where an externally managed config file should
+// be spliced into the generated output via @include/@INCLUDE.
+type InsertionPoint int
+
+const (
+	// HeadOfFile inserts the external config before anything else.
+	HeadOfFile InsertionPoint = iota
+	// AfterInputs inserts the external config after the INPUT/source blocks.
+	AfterInputs
+	// AfterFilters inserts the external config after the FILTER blocks.
+	AfterFilters
+	// EndOfFile inserts the external config after everything else.
+	EndOfFile
+)
+
+type ioBlock struct {
+	name   string
+	tag    string
+	params map[string]string
+	buffer *BufferOptions
+
+	// fluentdName and fluentdParams override name and params when rendering
+	// a Fluentd config, for plugins whose Fluent Bit and Fluentd names or
+	// key conventions differ. Left unset, the Fluentd renderer falls back
+	// to name/params.
+	fluentdName   string
+	fluentdParams map[string]string
+}
+
+type filterBlock struct {
+	kind    string // "include", "exclude", "record", "go", "lua" or "raw"
+	match   string
+	key     string
+	pattern string
+	records []fieldRecord
+
+	// name, path, script and call back the "go" and "lua" kinds; see
+	// AddGoFilter and AddLuaFilter. "raw" also uses name, for a built-in
+	// Fluent Bit filter plugin this package doesn't model (see
+	// parseBitGenericFilter in parser.go).
+	name   string
+	path   string
+	script string
+	call   string
+	extra  map[string]string
+}
+
+type fieldRecord struct {
+	key   string
+	value string
+}
+
+// Config accumulates inputs, filters, outputs and external config
+// references, and renders them into a Fluent Bit or Fluentd config file.
+type Config struct {
+	inputs  []ioBlock
+	filters []*filterBlock
+	outputs []ioBlock
+
+	externalConfigs map[InsertionPoint][]string
+	serviceStorage  *ServiceStorage
+	secretResolver  func(SecretRef) string
+	warnings        []string
+
+	custom map[string]interface{}
+
+	fluentBitTemplate string
+	fluentdTemplate   string
+}
+
+// New returns an empty Config ready to be populated with AddInput,
+// AddOutput, and friends.
+func New() *Config {
+	return &Config{
+		externalConfigs: make(map[InsertionPoint][]string),
+		custom:          make(map[string]interface{}),
+	}
+}
+
+// AddInput registers an INPUT/source block. name is the plugin name (e.g.
+// "forward"), tag is the Fluentd tag ("" to omit), and params are rendered
+// as plugin-specific key/value pairs in sorted order.
+func (c *Config) AddInput(name, tag string, params map[string]string) *Config {
+	c.inputs = append(c.inputs, ioBlock{name: name, tag: tag, params: params})
+	return c
+}
+
+// AddOutput registers an OUTPUT/match block. name is the plugin name (e.g.
+// "cloudwatch"), match is the Fluent Bit Match / Fluentd match pattern, and
+// params are rendered as plugin-specific key/value pairs in sorted order.
+func (c *Config) AddOutput(name, match string, params map[string]string) *Config {
+	c.outputs = append(c.outputs, ioBlock{name: name, tag: match, params: params})
+	return c
+}
+
+// AddIncludeFilter adds a grep filter that keeps records where key matches
+// pattern.
+func (c *Config) AddIncludeFilter(pattern, key, match string) *Config {
+	c.filters = append(c.filters, &filterBlock{kind: "include", match: match, key: key, pattern: pattern})
+	return c
+}
+
+// AddExcludeFilter adds a grep filter that drops records where key matches
+// pattern.
+func (c *Config) AddExcludeFilter(pattern, key, match string) *Config {
+	c.filters = append(c.filters, &filterBlock{kind: "exclude", match: match, key: key, pattern: pattern})
+	return c
+}
+
+// AddFieldToRecord adds a static key/value pair to every record matching
+// match, via Fluent Bit's record_modifier / Fluentd's record_transformer.
+// Successive calls for the same match are merged into a single filter
+// block.
+func (c *Config) AddFieldToRecord(key, value, match string) *Config {
+	for _, f := range c.filters {
+		if f.kind == "record" && f.match == match {
+			f.records = append(f.records, fieldRecord{key: key, value: value})
+			return c
+		}
+	}
+	c.filters = append(c.filters, &filterBlock{kind: "record", match: match, records: []fieldRecord{{key: key, value: value}}})
+	return c
+}
+
+// AddExternalConfig splices the contents of path into the generated config
+// at the given insertion point via @include/@INCLUDE.
+func (c *Config) AddExternalConfig(path string, point InsertionPoint) *Config {
+	c.externalConfigs[point] = append(c.externalConfigs[point], path)
+	return c
+}
+
+// AddCustom registers named data to be made available to a custom template
+// under .Custom.<name>, see WithFluentBitTemplate / WithFluentdTemplate.
+func (c *Config) AddCustom(custom map[string]interface{}) *Config {
+	for k, v := range custom {
+		c.custom[k] = v
+	}
+	return c
+}
+
+// WithFluentBitTemplate overrides the Fluent Bit output with a raw Go
+// template, rendered with a struct exposing .Custom.
+func (c *Config) WithFluentBitTemplate(tmpl string) *Config {
+	c.fluentBitTemplate = tmpl
+	return c
+}
+
+// WithFluentdTemplate overrides the Fluentd output with a raw Go template,
+// rendered with a struct exposing .Custom.
+func (c *Config) WithFluentdTemplate(tmpl string) *Config {
+	c.fluentdTemplate = tmpl
+	return c
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteFluentBitConfig renders the accumulated config as a Fluent Bit
+// config file and writes it to w.
+func (c *Config) WriteFluentBitConfig(w io.Writer) error {
+	if c.fluentBitTemplate != "" {
+		return c.writeCustomTemplate(w, c.fluentBitTemplate)
+	}
+
+	var sb strings.Builder
+
+	writeServiceStorage(&sb, c.serviceStorage)
+
+	writeIncludes(&sb, c.externalConfigs[HeadOfFile], "@INCLUDE")
+
+	for _, in := range c.inputs {
+		sb.WriteString("[INPUT]\n")
+		fmt.Fprintf(&sb, "    Name %s\n", in.name)
+		if in.tag != "" {
+			fmt.Fprintf(&sb, "    Tag %s\n", in.tag)
+		}
+		for _, k := range sortedKeys(in.params) {
+			fmt.Fprintf(&sb, "    %s %s\n", k, in.params[k])
+		}
+		sb.WriteString("\n")
+	}
+
+	writeIncludes(&sb, c.externalConfigs[AfterInputs], "@INCLUDE")
+
+	for _, f := range c.filters {
+		sb.WriteString("[FILTER]\n")
+		switch f.kind {
+		case "include":
+			sb.WriteString("    Name   grep\n")
+			fmt.Fprintf(&sb, "    Match %s\n", f.match)
+			fmt.Fprintf(&sb, "    Regex  %s %s\n", f.key, f.pattern)
+		case "exclude":
+			sb.WriteString("    Name   grep\n")
+			fmt.Fprintf(&sb, "    Match %s\n", f.match)
+			fmt.Fprintf(&sb, "    Exclude %s %s\n", f.key, f.pattern)
+		case "record":
+			sb.WriteString("    Name record_modifier\n")
+			fmt.Fprintf(&sb, "    Match %s\n", f.match)
+			for _, r := range f.records {
+				fmt.Fprintf(&sb, "    Record %s %s\n", r.key, r.value)
+			}
+		case "go":
+			fmt.Fprintf(&sb, "    Name %s\n", f.name)
+			fmt.Fprintf(&sb, "    Match %s\n", f.match)
+			fmt.Fprintf(&sb, "    Path %s\n", f.path)
+			for _, k := range sortedKeys(f.extra) {
+				fmt.Fprintf(&sb, "    %s %s\n", k, f.extra[k])
+			}
+		case "raw":
+			fmt.Fprintf(&sb, "    Name %s\n", f.name)
+			fmt.Fprintf(&sb, "    Match %s\n", f.match)
+			for _, k := range sortedKeys(f.extra) {
+				fmt.Fprintf(&sb, "    %s %s\n", k, f.extra[k])
+			}
+		case "lua":
+			sb.WriteString("    Name lua\n")
+			fmt.Fprintf(&sb, "    Match %s\n", f.match)
+			if f.name != "" {
+				fmt.Fprintf(&sb, "    Alias %s\n", f.name)
+			}
+			fmt.Fprintf(&sb, "    script %s\n", f.script)
+			fmt.Fprintf(&sb, "    call %s\n", f.call)
+			for _, k := range sortedKeys(f.extra) {
+				fmt.Fprintf(&sb, "    %s %s\n", k, f.extra[k])
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	writeIncludes(&sb, c.externalConfigs[AfterFilters], "@INCLUDE")
+
+	for _, out := range c.outputs {
+		sb.WriteString("[OUTPUT]\n")
+		fmt.Fprintf(&sb, "    Name %s\n", out.name)
+		fmt.Fprintf(&sb, "    Match %s\n", out.tag)
+		for _, k := range sortedKeys(out.params) {
+			fmt.Fprintf(&sb, "    %s %s\n", k, out.params[k])
+		}
+		writeFluentBitBuffer(&sb, out.buffer)
+		sb.WriteString("\n")
+	}
+
+	writeIncludes(&sb, c.externalConfigs[EndOfFile], "@INCLUDE")
+
+	rendered := c.resolveFluentBitSecretRefs(strings.TrimSuffix(sb.String(), "\n"))
+	_, err := io.WriteString(w, rendered)
+	return err
+}
+
+// WriteFluentdConfig renders the accumulated config as a Fluentd config
+// file and writes it to w.
+func (c *Config) WriteFluentdConfig(w io.Writer) error {
+	if c.fluentdTemplate != "" {
+		return c.writeCustomTemplate(w, c.fluentdTemplate)
+	}
+
+	var sb strings.Builder
+
+	writeIncludes(&sb, c.externalConfigs[HeadOfFile], "@include")
+
+	for _, in := range c.inputs {
+		sb.WriteString("<source>\n")
+		fmt.Fprintf(&sb, "    @type %s\n", in.name)
+		if in.tag != "" {
+			fmt.Fprintf(&sb, "    tag %s\n", in.tag)
+		}
+		for _, k := range sortedKeys(in.params) {
+			fmt.Fprintf(&sb, "    %s %s\n", k, in.params[k])
+		}
+		sb.WriteString("</source>\n\n")
+	}
+
+	writeIncludes(&sb, c.externalConfigs[AfterInputs], "@include")
+
+	for _, f := range c.filters {
+		if f.kind == "go" {
+			fmt.Fprintf(&sb, "# no Fluentd equivalent for Go filter %q, skipped\n\n", f.name)
+			continue
+		}
+		if f.kind == "raw" {
+			fmt.Fprintf(&sb, "# Fluent Bit filter %q has no known Fluentd mapping in this package, skipped\n\n", f.name)
+			continue
+		}
+
+		fmt.Fprintf(&sb, "<filter %s>\n", f.match)
+		switch f.kind {
+		case "include":
+			sb.WriteString("    @type  grep\n")
+			sb.WriteString("    <regexp>\n")
+			fmt.Fprintf(&sb, "        key %s\n", f.key)
+			fmt.Fprintf(&sb, "        pattern %s\n", f.pattern)
+			sb.WriteString("    </regexp>\n")
+		case "exclude":
+			sb.WriteString("    @type  grep\n")
+			sb.WriteString("    <exclude>\n")
+			fmt.Fprintf(&sb, "        key %s\n", f.key)
+			fmt.Fprintf(&sb, "        pattern %s\n", f.pattern)
+			sb.WriteString("    </exclude>\n")
+		case "record":
+			sb.WriteString("    @type record_transformer\n")
+			sb.WriteString("    <record>\n")
+			for _, r := range f.records {
+				fmt.Fprintf(&sb, "        %s %s\n", r.key, r.value)
+			}
+			sb.WriteString("    </record>\n")
+		case "lua":
+			sb.WriteString("    @type script\n")
+			if f.name != "" {
+				fmt.Fprintf(&sb, "    @id %s\n", f.name)
+			}
+			fmt.Fprintf(&sb, "    path %s\n", f.script)
+			fmt.Fprintf(&sb, "    call %s\n", f.call)
+			for _, k := range sortedKeys(f.extra) {
+				fmt.Fprintf(&sb, "    %s %s\n", k, f.extra[k])
+			}
+		}
+		sb.WriteString("</filter>\n\n")
+	}
+
+	writeIncludes(&sb, c.externalConfigs[AfterFilters], "@include")
+
+	for _, out := range c.outputs {
+		name := out.name
+		if out.fluentdName != "" {
+			name = out.fluentdName
+		}
+		params := out.params
+		if out.fluentdParams != nil {
+			params = out.fluentdParams
+		}
+		fmt.Fprintf(&sb, "<match %s>\n", out.tag)
+		fmt.Fprintf(&sb, "    @type %s\n", name)
+		for _, k := range sortedKeys(params) {
+			fmt.Fprintf(&sb, "    %s %s\n", k, params[k])
+		}
+		writeFluentdBuffer(&sb, out.buffer)
+		sb.WriteString("</match>\n\n")
+	}
+
+	writeIncludes(&sb, c.externalConfigs[EndOfFile], "@include")
+
+	rendered := c.resolveFluentdSecretRefs(strings.TrimSuffix(sb.String(), "\n"))
+	_, err := io.WriteString(w, rendered)
+	return err
+}
+
+func writeIncludes(sb *strings.Builder, paths []string, directive string) {
+	for _, p := range paths {
+		fmt.Fprintf(sb, "%s %s\n\n", directive, p)
+	}
+}
+
+func (c *Config) writeCustomTemplate(w io.Writer, raw string) error {
+	tmpl, err := template.New("config").Parse(raw)
+	if err != nil {
+		return err
+	}
+	data := struct {
+		Custom map[string]interface{}
+	}{Custom: c.custom}
+	return tmpl.Execute(w, data)
+}
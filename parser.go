@@ -0,0 +1,563 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// lastBlockSeen tracks which kind of section a parser has most recently
+// finished, so that an @include/@INCLUDE line between sections can be
+// attributed to the right InsertionPoint - the same bucketing
+// WriteFluentBitConfig/WriteFluentdConfig use to place them.
+type lastBlockSeen int
+
+const (
+	noneSeen lastBlockSeen = iota
+	inputSeen
+	filterSeen
+	outputSeen
+)
+
+func (l lastBlockSeen) insertionPoint() InsertionPoint {
+	switch l {
+	case inputSeen:
+		return AfterInputs
+	case filterSeen:
+		return AfterFilters
+	case outputSeen:
+		return EndOfFile
+	default:
+		return HeadOfFile
+	}
+}
+
+// splitField splits a "key rest-of-line" config line into its field name
+// and the remainder of the line.
+func splitField(line string) (field, rest string) {
+	parts := strings.SplitN(strings.TrimSpace(line), " ", 2)
+	field = parts[0]
+	if len(parts) > 1 {
+		rest = strings.TrimSpace(parts[1])
+	}
+	return field, rest
+}
+
+// ParseFluentBit reads a Fluent Bit config file written in the
+// "[SECTION]\n    key value" syntax WriteFluentBitConfig produces, and
+// populates a *Config with the same inputs, filters, outputs and external
+// config references.
+func ParseFluentBit(r io.Reader) (*Config, error) {
+	c := New()
+	scanner := bufio.NewScanner(r)
+
+	last := noneSeen
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "@INCLUDE "):
+			c.AddExternalConfig(strings.TrimSpace(strings.TrimPrefix(line, "@INCLUDE ")), last.insertionPoint())
+
+		case line == "[SERVICE]":
+			storage, err := parseBitServiceStorage(scanner)
+			if err != nil {
+				return nil, err
+			}
+			c.WithStorage(storage)
+
+		case line == "[INPUT]":
+			if err := parseBitInput(scanner, c); err != nil {
+				return nil, err
+			}
+			last = inputSeen
+
+		case line == "[FILTER]":
+			if err := parseBitFilter(scanner, c); err != nil {
+				return nil, err
+			}
+			last = filterSeen
+
+		case line == "[OUTPUT]":
+			if err := parseBitOutput(scanner, c); err != nil {
+				return nil, err
+			}
+			last = outputSeen
+
+		default:
+			return nil, fmt.Errorf("generator: unexpected line in Fluent Bit config: %q", line)
+		}
+	}
+	return c, scanner.Err()
+}
+
+func readBitBlock(scanner *bufio.Scanner) []string {
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func parseBitServiceStorage(scanner *bufio.Scanner) (ServiceStorage, error) {
+	var s ServiceStorage
+	for _, line := range readBitBlock(scanner) {
+		field, value := splitField(line)
+		switch field {
+		case "storage.path":
+			s.Path = value
+		case "storage.sync":
+			s.Sync = value
+		case "storage.checksum":
+			s.Checksum = value
+		case "storage.backlog.mem_limit":
+			s.BacklogMemLimit = value
+		}
+	}
+	return s, nil
+}
+
+func parseBitInput(scanner *bufio.Scanner, c *Config) error {
+	var name, tag string
+	params := map[string]string{}
+	for _, line := range readBitBlock(scanner) {
+		field, value := splitField(line)
+		switch field {
+		case "Name":
+			name = value
+		case "Tag":
+			tag = value
+		default:
+			params[field] = value
+		}
+	}
+	c.AddInput(name, tag, params)
+	return nil
+}
+
+func parseBitFilter(scanner *bufio.Scanner, c *Config) error {
+	lines := readBitBlock(scanner)
+	if len(lines) == 0 {
+		return fmt.Errorf("generator: empty [FILTER] block")
+	}
+
+	_, name := splitField(lines[0])
+	switch name {
+	case "grep":
+		return parseBitGrepFilter(lines, c)
+	case "record_modifier":
+		return parseBitRecordFilter(lines, c)
+	case "lua":
+		return parseBitLuaFilter(lines, c)
+	default:
+		if knownBitFilterPlugins[name] {
+			// A recognized built-in Fluent Bit filter plugin this
+			// package has no typed support for (e.g. kubernetes):
+			// round-trip it verbatim instead of guessing it's a
+			// custom Go plugin, which would fabricate a Path field
+			// and misreport it as having no Fluentd equivalent.
+			return parseBitGenericFilter(name, lines, c)
+		}
+		// Anything else is assumed to be a custom Go filter plugin,
+		// identified by the name it registered via FLBPluginRegister.
+		return parseBitGoFilter(name, lines, c)
+	}
+}
+
+// knownBitFilterPlugins lists Fluent Bit built-in filter plugins this
+// package doesn't model with a dedicated AddXFilter method, but that are
+// common enough in hand-written configs (chunk0-5's "legacy
+// operator-managed config" scenario) to need round-tripping instead of
+// being misclassified as custom Go plugins by parseBitFilter's default
+// case. Not exhaustive — extend as real-world configs surface gaps.
+var knownBitFilterPlugins = map[string]bool{
+	"kubernetes":  true,
+	"parser":      true,
+	"modify":      true,
+	"nest":        true,
+	"rewrite_tag": true,
+	"throttle":    true,
+	"multiline":   true,
+}
+
+func parseBitGenericFilter(name string, lines []string, c *Config) error {
+	var match string
+	extra := map[string]string{}
+	for _, line := range lines[1:] {
+		field, value := splitField(line)
+		switch field {
+		case "Match":
+			match = value
+		default:
+			extra[field] = value
+		}
+	}
+	c.filters = append(c.filters, &filterBlock{
+		kind:  "raw",
+		name:  name,
+		match: match,
+		extra: extra,
+	})
+	return nil
+}
+
+func parseBitGrepFilter(lines []string, c *Config) error {
+	var match string
+	for _, line := range lines[1:] {
+		field, value := splitField(line)
+		switch field {
+		case "Match":
+			match = value
+		case "Regex":
+			key, pattern := splitField(value)
+			c.AddIncludeFilter(pattern, key, match)
+		case "Exclude":
+			key, pattern := splitField(value)
+			c.AddExcludeFilter(pattern, key, match)
+		}
+	}
+	return nil
+}
+
+func parseBitRecordFilter(lines []string, c *Config) error {
+	var match string
+	for _, line := range lines[1:] {
+		field, value := splitField(line)
+		switch field {
+		case "Match":
+			match = value
+		case "Record":
+			key, recordValue := splitField(value)
+			c.AddFieldToRecord(key, recordValue, match)
+		}
+	}
+	return nil
+}
+
+func parseBitLuaFilter(lines []string, c *Config) error {
+	var match, alias, script, call string
+	extra := map[string]string{}
+	for _, line := range lines[1:] {
+		field, value := splitField(line)
+		switch field {
+		case "Match":
+			match = value
+		case "Alias":
+			alias = value
+		case "script":
+			script = value
+		case "call":
+			call = value
+		default:
+			extra[field] = value
+		}
+	}
+	c.AddLuaFilter(alias, script, call, match, extra)
+	return nil
+}
+
+func parseBitGoFilter(name string, lines []string, c *Config) error {
+	var match, path string
+	extra := map[string]string{}
+	for _, line := range lines[1:] {
+		field, value := splitField(line)
+		switch field {
+		case "Match":
+			match = value
+		case "Path":
+			path = value
+		default:
+			extra[field] = value
+		}
+	}
+	c.AddGoFilter(name, path, match, extra)
+	return nil
+}
+
+func parseBitOutput(scanner *bufio.Scanner, c *Config) error {
+	var name, match string
+	params := map[string]string{}
+	buffer := BufferOptions{}
+	hasBuffer := false
+	for _, line := range readBitBlock(scanner) {
+		field, value := splitField(line)
+		switch field {
+		case "Name":
+			name = value
+		case "Match":
+			match = value
+		case "storage.type":
+			buffer.StorageType = value
+			hasBuffer = true
+		case "Retry_Limit":
+			buffer.RetryLimit = value
+			hasBuffer = true
+		default:
+			params[field] = value
+		}
+	}
+	c.AddOutput(name, match, params)
+	if hasBuffer {
+		c.AddBuffer(match, buffer)
+	}
+	return nil
+}
+
+// ParseFluentd reads a Fluentd config file written in the
+// "<source>...</source>" / "<filter>...</filter>" / "<match>...</match>"
+// syntax WriteFluentdConfig produces, and populates a *Config with the
+// same inputs, filters, outputs and external config references.
+func ParseFluentd(r io.Reader) (*Config, error) {
+	c := New()
+	scanner := bufio.NewScanner(r)
+
+	last := noneSeen
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "#"):
+			// A comment, e.g. a note left by the Fluent Bit-only Go filter
+			// renderer that it has no Fluentd equivalent; nothing to parse.
+			continue
+
+		case strings.HasPrefix(line, "@include "):
+			c.AddExternalConfig(strings.TrimSpace(strings.TrimPrefix(line, "@include ")), last.insertionPoint())
+
+		case line == "<source>":
+			if err := parseFluentdInput(scanner, c); err != nil {
+				return nil, err
+			}
+			last = inputSeen
+
+		case strings.HasPrefix(line, "<filter "):
+			match := strings.TrimSuffix(strings.TrimPrefix(line, "<filter "), ">")
+			if err := parseFluentdFilter(scanner, c, match); err != nil {
+				return nil, err
+			}
+			last = filterSeen
+
+		case strings.HasPrefix(line, "<match "):
+			match := strings.TrimSuffix(strings.TrimPrefix(line, "<match "), ">")
+			if err := parseFluentdOutput(scanner, c, match); err != nil {
+				return nil, err
+			}
+			last = outputSeen
+
+		default:
+			return nil, fmt.Errorf("generator: unexpected line in Fluentd config: %q", line)
+		}
+	}
+	return c, scanner.Err()
+}
+
+// readFluentdBlock reads lines up to (and consuming) a line equal to
+// closeTag, returning the trimmed lines in between; blank lines inside the
+// block are returned as empty strings. It returns an error if the input
+// ends before closeTag is found.
+func readFluentdBlock(scanner *bufio.Scanner, closeTag string) ([]string, error) {
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == closeTag {
+			return lines, nil
+		}
+		lines = append(lines, line)
+	}
+	return nil, fmt.Errorf("generator: missing closing %q", closeTag)
+}
+
+func parseFluentdInput(scanner *bufio.Scanner, c *Config) error {
+	lines, err := readFluentdBlock(scanner, "</source>")
+	if err != nil {
+		return err
+	}
+	var name, tag string
+	params := map[string]string{}
+	for _, line := range lines {
+		field, value := splitField(line)
+		switch field {
+		case "@type":
+			name = value
+		case "tag":
+			tag = value
+		default:
+			params[field] = value
+		}
+	}
+	c.AddInput(name, tag, params)
+	return nil
+}
+
+func parseFluentdFilter(scanner *bufio.Scanner, c *Config, match string) error {
+	lines, err := readFluentdBlock(scanner, "</filter>")
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return fmt.Errorf("generator: empty <filter> block")
+	}
+
+	_, pluginType := splitField(lines[0])
+	switch pluginType {
+	case "grep":
+		return parseFluentdGrepFilter(lines[1:], c, match)
+	case "record_transformer":
+		return parseFluentdRecordFilter(lines[1:], c, match)
+	case "script":
+		return parseFluentdLuaFilter(lines[1:], c, match)
+	default:
+		return fmt.Errorf("generator: unsupported Fluentd filter plugin %q", pluginType)
+	}
+}
+
+func parseFluentdLuaFilter(lines []string, c *Config, match string) error {
+	var id, script, call string
+	extra := map[string]string{}
+	for _, line := range lines {
+		field, value := splitField(line)
+		switch field {
+		case "@id":
+			id = value
+		case "path":
+			script = value
+		case "call":
+			call = value
+		default:
+			extra[field] = value
+		}
+	}
+	c.AddLuaFilter(id, script, call, match, extra)
+	return nil
+}
+
+func parseFluentdGrepFilter(lines []string, c *Config, match string) error {
+	// lines is everything between "@type  grep" and "</filter>", which for
+	// our own renderer is exactly one "<regexp>"/"<exclude>" block with a
+	// key and pattern line.
+	if len(lines) < 3 {
+		return fmt.Errorf("generator: malformed grep filter")
+	}
+	kind := strings.TrimSuffix(strings.TrimPrefix(lines[0], "<"), ">")
+
+	var key, pattern string
+	for _, line := range lines[1:] {
+		field, value := splitField(line)
+		switch field {
+		case "key":
+			key = value
+		case "pattern":
+			pattern = value
+		}
+	}
+
+	switch kind {
+	case "regexp":
+		c.AddIncludeFilter(pattern, key, match)
+	case "exclude":
+		c.AddExcludeFilter(pattern, key, match)
+	default:
+		return fmt.Errorf("generator: unsupported grep filter block %q", kind)
+	}
+	return nil
+}
+
+func parseFluentdRecordFilter(lines []string, c *Config, match string) error {
+	for _, line := range lines {
+		if line == "<record>" || line == "</record>" {
+			continue
+		}
+		key, value := splitField(line)
+		c.AddFieldToRecord(key, value, match)
+	}
+	return nil
+}
+
+func parseFluentdOutput(scanner *bufio.Scanner, c *Config, match string) error {
+	lines, err := readFluentdBlock(scanner, "</match>")
+	if err != nil {
+		return err
+	}
+
+	var name string
+	params := map[string]string{}
+	buffer := BufferOptions{}
+	hasBuffer := false
+	inBuffer := false
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "<buffer"):
+			inBuffer = true
+			hasBuffer = true
+			continue
+		case line == "</buffer>":
+			inBuffer = false
+			continue
+		}
+
+		field, value := splitField(line)
+		if inBuffer {
+			switch field {
+			case "@type":
+				buffer.StorageType = fluentdStorageTypeToBit(value)
+			case "path":
+				buffer.Path = value
+			case "chunk_limit_size":
+				buffer.ChunkLimitSize = value
+			case "flush_interval":
+				buffer.FlushInterval = value
+			case "retry_type":
+				buffer.RetryType = value
+			case "retry_max_interval":
+				buffer.RetryMaxInterval = value
+			case "overflow_action":
+				buffer.OverflowAction = value
+			}
+			continue
+		}
+
+		switch field {
+		case "@type":
+			name = value
+		default:
+			params[field] = value
+		}
+	}
+
+	c.AddOutput(name, match, params)
+	if hasBuffer {
+		c.AddBuffer(match, buffer)
+	}
+	return nil
+}
+
+func fluentdStorageTypeToBit(fluentdType string) string {
+	if fluentdType == "file" {
+		return "filesystem"
+	}
+	return fluentdType
+}
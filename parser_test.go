@@ -0,0 +1,116 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFluentBitRoundTrip(t *testing.T) {
+	config := New()
+	config.AddInput("forward", "tag", map[string]string{
+		"Listen": "127.0.0.1",
+		"Port":   "24224",
+	})
+	config.AddIncludeFilter("*failure*", "log", "*").AddExcludeFilter("*success*", "log", "*")
+	config.AddFieldToRecord("cluster", "default", "*")
+	config.AddOutput("cloudwatch", "*", map[string]string{
+		"log_group_name": "my-group",
+		"region":         "us-west-2",
+	})
+	config.AddBuffer("*", BufferOptions{StorageType: "filesystem", RetryLimit: "5"})
+
+	original := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentBitConfig(original))
+
+	parsed, err := ParseFluentBit(strings.NewReader(original.String()))
+	assert.NoError(t, err)
+
+	roundTripped := new(bytes.Buffer)
+	assert.NoError(t, parsed.WriteFluentBitConfig(roundTripped))
+
+	assert.Equal(t, original.String(), roundTripped.String())
+}
+
+func TestParseFluentdRoundTrip(t *testing.T) {
+	config := New()
+	config.AddInput("forward", "tag", map[string]string{
+		"Listen": "127.0.0.1",
+		"Port":   "24224",
+	})
+	config.AddIncludeFilter("*failure*", "log", "*").AddExcludeFilter("*success*", "log", "*")
+	config.AddFieldToRecord("cluster", "default", "*")
+	config.AddOutput("cloudwatch", "*", map[string]string{
+		"log_group_name": "my-group",
+		"region":         "us-west-2",
+	})
+	config.AddBuffer("*", BufferOptions{StorageType: "filesystem", Path: "/var/log/fluent/buffer"})
+
+	original := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentdConfig(original))
+
+	parsed, err := ParseFluentd(strings.NewReader(original.String()))
+	assert.NoError(t, err)
+
+	roundTripped := new(bytes.Buffer)
+	assert.NoError(t, parsed.WriteFluentdConfig(roundTripped))
+
+	assert.Equal(t, original.String(), roundTripped.String())
+}
+
+func TestParseFluentBitWithExternalConfigs(t *testing.T) {
+	config := New()
+	config.AddExternalConfig("/etc/head_file.conf", HeadOfFile)
+	config.AddInput("forward", "tag", map[string]string{"Listen": "127.0.0.1"})
+	config.AddExternalConfig("/etc/after_inputs.conf", AfterInputs)
+	config.AddIncludeFilter("*failure*", "log", "*")
+	config.AddExternalConfig("/etc/after_filters.conf", AfterFilters)
+	config.AddOutput("firehose", "*", map[string]string{"delivery_stream": "my-stream"})
+	config.AddExternalConfig("/etc/end_file.conf", EndOfFile)
+
+	original := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentBitConfig(original))
+
+	parsed, err := ParseFluentBit(strings.NewReader(original.String()))
+	assert.NoError(t, err)
+
+	roundTripped := new(bytes.Buffer)
+	assert.NoError(t, parsed.WriteFluentBitConfig(roundTripped))
+
+	assert.Equal(t, original.String(), roundTripped.String())
+}
+
+func TestParseFluentBitKnownBuiltinFilterRoundTrips(t *testing.T) {
+	// kubernetes is a common real-world Fluent Bit filter this package
+	// doesn't model with an Add*Filter method; it must round-trip as-is
+	// rather than being guessed as a custom Go plugin.
+	original := "[FILTER]\n    Name kubernetes\n    Match kube.*\n    Kube_URL https://kubernetes.default.svc:443\n"
+
+	parsed, err := ParseFluentBit(strings.NewReader(original))
+	assert.NoError(t, err)
+
+	roundTripped := new(bytes.Buffer)
+	assert.NoError(t, parsed.WriteFluentBitConfig(roundTripped))
+	assert.Equal(t, original, roundTripped.String())
+
+	fluentdOut := new(bytes.Buffer)
+	assert.NoError(t, parsed.WriteFluentdConfig(fluentdOut))
+	assert.Equal(t, "# Fluent Bit filter \"kubernetes\" has no known Fluentd mapping in this package, skipped\n", fluentdOut.String())
+
+	assert.Empty(t, parsed.Warnings())
+}
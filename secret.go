@@ -0,0 +1,176 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SecretRef points at a credential that should not be inlined into a
+// rendered config. Its String() form is a placeholder that can be passed
+// anywhere a map[string]string value is expected today (AddInput,
+// AddOutput, AddElasticsearchOutput, ...); WriteFluentBitConfig and
+// WriteFluentdConfig replace every placeholder with an environment
+// variable interpolation before writing, keyed by Config.secretResolver.
+type SecretRef struct {
+	Name string
+	Key  string
+
+	// ValueFrom optionally names the environment variable that already
+	// holds this secret's value at runtime, e.g. one injected by a
+	// Kubernetes secretKeyRef env binding. When set it is used verbatim
+	// in place of Config.secretResolver/Name for this one reference.
+	ValueFrom string
+}
+
+// String renders the placeholder form of the reference, suitable for use
+// as a map[string]string value passed to the rest of the package's API.
+func (s SecretRef) String() string {
+	if s.ValueFrom != "" {
+		return fmt.Sprintf("${secretref:%s/%s#%s}", s.Name, s.Key, s.ValueFrom)
+	}
+	return fmt.Sprintf("${secretref:%s/%s}", s.Name, s.Key)
+}
+
+var secretRefPattern = regexp.MustCompile(`\$\{secretref:([^/}]+)/([^}#]+)(?:#([^}]+))?\}`)
+
+func secretRefFromMatch(sub []string) SecretRef {
+	return SecretRef{Name: sub[1], Key: sub[2], ValueFrom: sub[3]}
+}
+
+// WithSecretResolver registers a callback that maps a SecretRef to the
+// name of the environment variable that will hold its value at runtime.
+// Without a resolver, the secret's Name is sanitized into an environment
+// variable name (see sanitizeEnvVarName). A reference with ValueFrom set
+// bypasses both and uses ValueFrom directly.
+func (c *Config) WithSecretResolver(resolver func(SecretRef) string) *Config {
+	c.secretResolver = resolver
+	return c
+}
+
+func (c *Config) envVarFor(ref SecretRef) string {
+	if ref.ValueFrom != "" {
+		return ref.ValueFrom
+	}
+	if c.secretResolver != nil {
+		return c.secretResolver(ref)
+	}
+	return sanitizeEnvVarName(ref.Name)
+}
+
+var envVarSanitizer = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// sanitizeEnvVarName turns an arbitrary secret name into a valid
+// shell/Fluent Bit environment variable name: upper-cased, with every
+// character outside [A-Za-z0-9_] replaced by "_", and a leading "_"
+// inserted if the result would otherwise start with a digit. Without
+// this, a secret name like "es-credentials" would render as
+// "${es-credentials}", which Fluent Bit and the shell both fail to
+// expand since "-" isn't a valid identifier character.
+func sanitizeEnvVarName(name string) string {
+	sanitized := envVarSanitizer.ReplaceAllString(strings.ToUpper(name), "_")
+	if sanitized != "" && sanitized[0] >= '0' && sanitized[0] <= '9' {
+		sanitized = "_" + sanitized
+	}
+	return sanitized
+}
+
+func resolveSecretRefs(rendered string, resolve func(SecretRef) string) string {
+	return secretRefPattern.ReplaceAllStringFunc(rendered, func(match string) string {
+		sub := secretRefPattern.FindStringSubmatch(match)
+		return resolve(secretRefFromMatch(sub))
+	})
+}
+
+func (c *Config) resolveFluentBitSecretRefs(rendered string) string {
+	return resolveSecretRefs(rendered, func(ref SecretRef) string {
+		return fmt.Sprintf("${%s}", c.envVarFor(ref))
+	})
+}
+
+func (c *Config) resolveFluentdSecretRefs(rendered string) string {
+	return resolveSecretRefs(rendered, func(ref SecretRef) string {
+		return fmt.Sprintf("\"#{ENV['%s']}\"", c.envVarFor(ref))
+	})
+}
+
+// Secrets returns every SecretRef referenced anywhere in the config, in a
+// stable order, for a companion manifest an operator can use to mount the
+// right secrets alongside the generated config.
+func (c *Config) Secrets() []SecretRef {
+	seen := make(map[SecretRef]bool)
+	var refs []SecretRef
+	collect := func(v string) {
+		for _, m := range secretRefPattern.FindAllStringSubmatch(v, -1) {
+			ref := secretRefFromMatch(m)
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	for _, in := range c.inputs {
+		for _, v := range in.params {
+			collect(v)
+		}
+	}
+	for _, f := range c.filters {
+		collect(f.pattern)
+		for _, r := range f.records {
+			collect(r.value)
+		}
+		for _, v := range f.extra {
+			collect(v)
+		}
+	}
+	for _, out := range c.outputs {
+		for _, v := range out.params {
+			collect(v)
+		}
+		for _, v := range out.fluentdParams {
+			collect(v)
+		}
+		if out.buffer != nil {
+			collect(out.buffer.Path)
+		}
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Name != refs[j].Name {
+			return refs[i].Name < refs[j].Name
+		}
+		if refs[i].Key != refs[j].Key {
+			return refs[i].Key < refs[j].Key
+		}
+		return refs[i].ValueFrom < refs[j].ValueFrom
+	})
+	return refs
+}
+
+// WriteSecretManifest writes one "ENV_VAR SecretName/SecretKey" line per
+// secret referenced in the config, so an operator knows which secrets to
+// mount and under which environment variable names.
+func (c *Config) WriteSecretManifest(w io.Writer) error {
+	for _, ref := range c.Secrets() {
+		if _, err := fmt.Fprintf(w, "%s %s/%s\n", c.envVarFor(ref), ref.Name, ref.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretRefInterpolation(t *testing.T) {
+	passwordRef := SecretRef{Name: "es-credentials", Key: "password"}
+
+	config := New()
+	config.WithSecretResolver(func(ref SecretRef) string {
+		return "ES_PASSWORD"
+	})
+	config.AddOutput("es", "*", map[string]string{
+		"HTTP_Passwd": passwordRef.String(),
+		"Host":        "es.example.com",
+	})
+
+	var expectedFluentBitConfig = `[OUTPUT]
+    Name es
+    Match *
+    HTTP_Passwd ${ES_PASSWORD}
+    Host es.example.com
+`
+
+	var expectedFluentdConfig = `<match *>
+    @type es
+    HTTP_Passwd "#{ENV['ES_PASSWORD']}"
+    Host es.example.com
+</match>
+`
+
+	fluentbitConfig := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentBitConfig(fluentbitConfig))
+	assert.Equal(t, expectedFluentBitConfig, fluentbitConfig.String())
+
+	fluentDConfig := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentdConfig(fluentDConfig))
+	assert.Equal(t, expectedFluentdConfig, fluentDConfig.String())
+}
+
+func TestSecretRefDefaultEnvVar(t *testing.T) {
+	ref := SecretRef{Name: "db-credentials", Key: "password"}
+
+	config := New()
+	config.AddOutput("mysql", "*", map[string]string{
+		"password": ref.String(),
+	})
+
+	var expected = `[OUTPUT]
+    Name mysql
+    Match *
+    password ${DB_CREDENTIALS}
+`
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentBitConfig(buf))
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestSecretRefValueFromOverridesResolver(t *testing.T) {
+	ref := SecretRef{Name: "es-credentials", Key: "password", ValueFrom: "ES_PASSWORD_FROM_K8S"}
+
+	config := New()
+	config.WithSecretResolver(func(ref SecretRef) string {
+		return "SHOULD_NOT_BE_USED"
+	})
+	config.AddOutput("es", "*", map[string]string{
+		"HTTP_Passwd": ref.String(),
+	})
+
+	var expected = `[OUTPUT]
+    Name es
+    Match *
+    HTTP_Passwd ${ES_PASSWORD_FROM_K8S}
+`
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, config.WriteFluentBitConfig(buf))
+	assert.Equal(t, expected, buf.String())
+
+	secrets := config.Secrets()
+	assert.Len(t, secrets, 1)
+	assert.Equal(t, ref, secrets[0])
+}
+
+func TestSecretManifest(t *testing.T) {
+	config := New()
+	config.WithSecretResolver(func(ref SecretRef) string {
+		return "ES_PASSWORD"
+	})
+	config.AddOutput("es", "*", map[string]string{
+		"HTTP_Passwd": SecretRef{Name: "es-credentials", Key: "password"}.String(),
+	})
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, config.WriteSecretManifest(buf))
+	assert.Equal(t, "ES_PASSWORD es-credentials/password\n", buf.String())
+}
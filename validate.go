@@ -0,0 +1,122 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// durationPattern matches Fluentd/Fluent Bit duration values: a plain
+// number of seconds, or a number suffixed with one of the ns/us/ms/s/m/h
+// units fluent time parsers accept.
+var durationPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h)?$`)
+
+// requiredOutputFields lists the keys each well-known output plugin needs
+// in order to start successfully.
+var requiredOutputFields = map[string][]string{
+	"cloudwatch": {"region", "log_group_name"},
+	"firehose":   {"region", "delivery_stream"},
+	"es":         {"Host"},
+}
+
+// requiredOutputFieldAlternatives lists, for a plugin and one of its
+// requiredOutputFields entries, a different field that satisfies the same
+// requirement. Elastic Cloud deployments (AddElasticsearchOutput's
+// CloudID/CloudAuth) authenticate via Cloud_ID instead of Host/Port.
+var requiredOutputFieldAlternatives = map[string]map[string]string{
+	"es": {"Host": "Cloud_ID"},
+}
+
+// durationOutputFields are the generic output keys checked against
+// durationPattern when present.
+var durationOutputFields = []string{"flush_interval", "retry_wait"}
+
+// ValidationError describes a single problem found by Config.Validate,
+// identifying the offending block by its position and plugin name so
+// callers can report everything wrong with a config in one pass.
+type ValidationError struct {
+	Index  int
+	Plugin string
+	Field  string
+	Err    error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("block #%d (%s): field %q: %v", e.Index, e.Plugin, e.Field, e.Err)
+}
+
+// ValidationErrors accumulates every ValidationError found during a single
+// Validate call.
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validate checks the accumulated filters and outputs for problems that
+// would otherwise only surface once Fluentd or Fluent Bit tries to start:
+// invalid grep regexes, outputs missing required fields, and malformed
+// duration values. Callers should run it before WriteFluentBitConfig /
+// WriteFluentdConfig. It returns nil if the config is valid, or a
+// ValidationErrors listing every problem found.
+func (c *Config) Validate() error {
+	var errs ValidationErrors
+
+	for i, f := range c.filters {
+		if f.kind != "include" && f.kind != "exclude" {
+			continue
+		}
+		if _, err := regexp.Compile(f.pattern); err != nil {
+			errs = append(errs, &ValidationError{Index: i, Plugin: "grep", Field: "Regex", Err: err})
+		}
+	}
+
+	for i, out := range c.outputs {
+		for _, field := range requiredOutputFields[out.name] {
+			if out.params[field] != "" {
+				continue
+			}
+			if alt, ok := requiredOutputFieldAlternatives[out.name][field]; ok && out.params[alt] != "" {
+				continue
+			}
+			errs = append(errs, &ValidationError{Index: i, Plugin: out.name, Field: field, Err: fmt.Errorf("required field is missing")})
+		}
+
+		for _, field := range durationOutputFields {
+			if v, ok := out.params[field]; ok && !durationPattern.MatchString(v) {
+				errs = append(errs, &ValidationError{Index: i, Plugin: out.name, Field: field, Err: fmt.Errorf("invalid duration %q", v)})
+			}
+		}
+
+		if b := out.buffer; b != nil {
+			if b.FlushInterval != "" && !durationPattern.MatchString(b.FlushInterval) {
+				errs = append(errs, &ValidationError{Index: i, Plugin: out.name, Field: "FlushInterval", Err: fmt.Errorf("invalid duration %q", b.FlushInterval)})
+			}
+			if b.RetryMaxInterval != "" && !durationPattern.MatchString(b.RetryMaxInterval) {
+				errs = append(errs, &ValidationError{Index: i, Plugin: out.name, Field: "RetryMaxInterval", Err: fmt.Errorf("invalid duration %q", b.RetryMaxInterval)})
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateValid(t *testing.T) {
+	config := New()
+	config.AddIncludeFilter(".*failure.*", "log", "*")
+	config.AddOutput("cloudwatch", "*", map[string]string{
+		"region":         "us-west-2",
+		"log_group_name": "my-group",
+		"flush_interval": "5s",
+	})
+
+	assert.NoError(t, config.Validate())
+}
+
+func TestValidateInvalidRegex(t *testing.T) {
+	config := New()
+	config.AddIncludeFilter("*(unterminated", "log", "*")
+
+	err := config.Validate()
+	assert.Error(t, err)
+	verrs, ok := err.(ValidationErrors)
+	assert.True(t, ok)
+	assert.Len(t, verrs, 1)
+	assert.Equal(t, "grep", verrs[0].Plugin)
+}
+
+func TestValidateMissingRequiredField(t *testing.T) {
+	config := New()
+	config.AddOutput("cloudwatch", "*", map[string]string{
+		"log_group_name": "my-group",
+	})
+
+	err := config.Validate()
+	assert.Error(t, err)
+	verrs, ok := err.(ValidationErrors)
+	assert.True(t, ok)
+	assert.Len(t, verrs, 1)
+	assert.Equal(t, "region", verrs[0].Field)
+}
+
+func TestValidateElasticsearchCloudIDSatisfiesHost(t *testing.T) {
+	config := New()
+	config.AddElasticsearchOutput("*", ElasticsearchOutputOptions{
+		CloudID:   "my-deployment:ZXUtd2VzdC0x",
+		CloudAuth: "elastic:password",
+	})
+
+	assert.NoError(t, config.Validate())
+}
+
+func TestValidateElasticsearchMissingHostAndCloudID(t *testing.T) {
+	config := New()
+	config.AddElasticsearchOutput("*", ElasticsearchOutputOptions{
+		IndexName: "myapp",
+	})
+
+	err := config.Validate()
+	assert.Error(t, err)
+	verrs, ok := err.(ValidationErrors)
+	assert.True(t, ok)
+	assert.Len(t, verrs, 1)
+	assert.Equal(t, "Host", verrs[0].Field)
+}
+
+func TestValidateInvalidDuration(t *testing.T) {
+	config := New()
+	config.AddOutput("cloudwatch", "*", map[string]string{
+		"region":         "us-west-2",
+		"log_group_name": "my-group",
+		"flush_interval": "soon",
+	})
+
+	err := config.Validate()
+	assert.Error(t, err)
+	verrs, ok := err.(ValidationErrors)
+	assert.True(t, ok)
+	assert.Len(t, verrs, 1)
+	assert.Equal(t, "flush_interval", verrs[0].Field)
+}